@@ -0,0 +1,21 @@
+package main
+
+// temporalBackend starts and stops a Temporal dev server on behalf of
+// NewTemporalService, either embedded in-process (see embedded.go, only
+// built when the headless build tag is absent) or by shelling out to the
+// temporal CLI (see cli_backend.go).
+type temporalBackend interface {
+	// Start brings the Temporal server up on the ports reserved in ports.
+	Start(cfg *Config, ports *PortConfig) error
+	// Stop tears down whatever Start brought up.
+	Stop() error
+}
+
+// supervisorProvider is implemented by backends that have a Supervisor to
+// expose - both cliBackend (supervising a spawned child) and embeddedBackend
+// (recording its own liveness). TemporalService.Supervisor() type-asserts
+// its backend against this interface to decide whether to mount the
+// /supervisor HTTP endpoints.
+type supervisorProvider interface {
+	Supervisor() *Supervisor
+}
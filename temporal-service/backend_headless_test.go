@@ -0,0 +1,13 @@
+//go:build headless
+
+package main
+
+import "testing"
+
+var _ temporalBackend = (*cliBackend)(nil)
+
+func TestNewTemporalBackendReturnsNilInHeadlessBuilds(t *testing.T) {
+	if backend := newTemporalBackend(&Config{}); backend != nil {
+		t.Errorf("expected a headless build's newTemporalBackend to return nil, got %T", backend)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !headless
+
+package main
+
+import "testing"
+
+// Compile-time checks that both backends satisfy temporalBackend; a
+// mismatched method signature here would be a build failure rather than a
+// test failure, but keeping it as a test documents the intent.
+var (
+	_ temporalBackend    = (*cliBackend)(nil)
+	_ temporalBackend    = (*embeddedBackend)(nil)
+	_ supervisorProvider = (*cliBackend)(nil)
+	_ supervisorProvider = (*embeddedBackend)(nil)
+)
+
+func TestNewTemporalBackendReturnsEmbeddedByDefault(t *testing.T) {
+	backend := newTemporalBackend(&Config{})
+
+	if _, ok := backend.(*embeddedBackend); !ok {
+		t.Errorf("expected a non-headless build's newTemporalBackend to return *embeddedBackend, got %T", backend)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"temporal-service/i18n"
+	"temporal-service/i18n/extract"
+)
+
+const (
+	defaultDBFilename = "temporal.db"
+	defaultLogFormat  = "pretty"
+)
+
+// validLogFormats mirrors the formats temporalite accepts for --log-format.
+var validLogFormats = map[string]bool{"json": true, "pretty": true, "noop": true}
+
+// Config holds all runtime configuration for the temporal-service binary.
+// It is built once by the CLI layer and threaded through to
+// NewTemporalService, rather than being re-read from the environment deep
+// inside the service.
+type Config struct {
+	Lang     string
+	Headless bool
+
+	TemporalPort int
+	UIPort       int
+	HTTPPort     int
+
+	DBFilename         string
+	LogFormat          string
+	Ephemeral          bool
+	Namespace          string
+	DynamicConfigValue dynamicConfigValues
+}
+
+// dynamicConfigValues implements pflag.Value so --dynamic-config-value can
+// be passed multiple times on the command line, one key=val pair each time.
+type dynamicConfigValues map[string]string
+
+func (d dynamicConfigValues) String() string {
+	pairs := make([]string, 0, len(d))
+	for k, v := range d {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (d dynamicConfigValues) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("invalid --dynamic-config-value %q, expected key=val", s)
+	}
+	d[key] = value
+	return nil
+}
+
+func (d dynamicConfigValues) Type() string {
+	return "key=val"
+}
+
+// newRootCmd builds the temporal-service command tree: start, status, stop.
+func newRootCmd() *cobra.Command {
+	cfg := &Config{
+		DBFilename:         defaultDBFilename,
+		LogFormat:          defaultLogFormat,
+		Namespace:          Namespace,
+		DynamicConfigValue: dynamicConfigValues{},
+	}
+
+	root := &cobra.Command{
+		Use:           "temporal-service",
+		Short:         "Manage the embedded Temporal server used by goose recipes",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+	}
+	root.PersistentFlags().StringVar(&cfg.Lang, "lang", i18n.GetLocale(), "UI language (e.g. en, pt-BR)")
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the Temporal service and HTTP API",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !validLogFormats[cfg.LogFormat] {
+				return fmt.Errorf("invalid --log-format %q, must be one of json|pretty|noop", cfg.LogFormat)
+			}
+			if cfg.HTTPPort == 0 {
+				if portEnv := os.Getenv("PORT"); portEnv != "" {
+					if parsed, err := strconv.Atoi(portEnv); err == nil {
+						cfg.HTTPPort = parsed
+					}
+				}
+			}
+			return runStart(cfg)
+		},
+	}
+	startCmd.Flags().IntVar(&cfg.TemporalPort, "temporal-port", 0, "Temporal server port (0 = auto-select starting at 7233)")
+	startCmd.Flags().IntVar(&cfg.UIPort, "ui-port", 0, "Temporal UI port (0 = auto-select starting at 8233)")
+	startCmd.Flags().IntVar(&cfg.HTTPPort, "http-port", 0, "HTTP API port (0 = $PORT, or auto-select starting at 8080)")
+	startCmd.Flags().StringVar(&cfg.DBFilename, "db-filename", cfg.DBFilename, "SQLite file used to persist Temporal server state")
+	startCmd.Flags().StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "Temporal server log format: json|pretty|noop")
+	startCmd.Flags().BoolVar(&cfg.Ephemeral, "ephemeral", false, "discard Temporal server state on exit instead of persisting to --db-filename")
+	startCmd.Flags().StringVar(&cfg.Namespace, "namespace", cfg.Namespace, "Temporal namespace to register and connect to")
+	startCmd.Flags().Var(cfg.DynamicConfigValue, "dynamic-config-value", "dynamic config override as key=val (repeatable)")
+	startCmd.Flags().BoolVar(&cfg.Headless, "headless", false, "skip starting the Temporal web UI")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report whether a Temporal service is already running",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(cfg)
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a running Temporal service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStop(cfg)
+		},
+	}
+
+	extractCmd := &cobra.Command{
+		Use:   "extract [srcDir]",
+		Short: "Walk Go source for i18n.T/Tf/Tn calls and regenerate messages/en.json",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcDir := "."
+			if len(args) > 0 {
+				srcDir = args[0]
+			}
+
+			ids, err := extract.MessageIDs(srcDir)
+			if err != nil {
+				return fmt.Errorf("failed to extract message IDs: %w", err)
+			}
+
+			enPath := filepath.Join(srcDir, "i18n", "messages", "en.json")
+			if err := extract.UpdateMessageFile(enPath, ids); err != nil {
+				return fmt.Errorf("failed to update %s: %w", enPath, err)
+			}
+
+			fmt.Printf("Wrote %d message IDs to %s\n", len(ids), enPath)
+			return nil
+		},
+	}
+
+	root.AddCommand(startCmd, statusCmd, stopCmd, extractCmd)
+	return root
+}
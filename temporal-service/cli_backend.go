@@ -0,0 +1,33 @@
+package main
+
+// cliBackend starts Temporal by discovering and exec'ing an external
+// `temporal` CLI binary. It is the backend used in headless builds, and the
+// fallback for normal builds if the embedded backend can't be constructed.
+//
+// Lifecycle is delegated to a Supervisor, which writes a PID/port file,
+// captures the child's logs, and restarts it on an unexpected exit.
+type cliBackend struct {
+	supervisor *Supervisor
+}
+
+func (b *cliBackend) Start(cfg *Config, ports *PortConfig) error {
+	supervisor, err := NewSupervisor(cfg)
+	if err != nil {
+		return err
+	}
+	b.supervisor = supervisor
+	return supervisor.Start(ports)
+}
+
+func (b *cliBackend) Stop() error {
+	if b.supervisor == nil {
+		return nil
+	}
+	return b.supervisor.Stop()
+}
+
+// Supervisor exposes the supervisor backing this cliBackend so the HTTP
+// layer can mount /supervisor endpoints against it.
+func (b *cliBackend) Supervisor() *Supervisor {
+	return b.supervisor
+}
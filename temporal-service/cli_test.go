@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestDynamicConfigValuesSet(t *testing.T) {
+	d := dynamicConfigValues{}
+
+	if err := d.Set("matching.numTaskqueueReadPartitions=3"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if got := d["matching.numTaskqueueReadPartitions"]; got != "3" {
+		t.Errorf("expected value %q, got %q", "3", got)
+	}
+
+	if err := d.Set("missing-equals-sign"); err == nil {
+		t.Error("expected an error for a value without key=val form")
+	}
+}
+
+func TestNewRootCmdParsesStartFlags(t *testing.T) {
+	root := newRootCmd()
+
+	startCmd, _, err := root.Find([]string{"start"})
+	if err != nil {
+		t.Fatalf("failed to find start subcommand: %v", err)
+	}
+
+	if err := startCmd.Flags().Parse([]string{
+		"--temporal-port", "17233",
+		"--ui-port", "18233",
+		"--ephemeral",
+		"--dynamic-config-value", "foo=bar",
+	}); err != nil {
+		t.Fatalf("failed to parse start flags: %v", err)
+	}
+
+	if port, _ := startCmd.Flags().GetInt("temporal-port"); port != 17233 {
+		t.Errorf("expected temporal-port 17233, got %d", port)
+	}
+	if port, _ := startCmd.Flags().GetInt("ui-port"); port != 18233 {
+		t.Errorf("expected ui-port 18233, got %d", port)
+	}
+	if ephemeral, _ := startCmd.Flags().GetBool("ephemeral"); !ephemeral {
+		t.Error("expected ephemeral to be true")
+	}
+
+	dynamicValue, ok := startCmd.Flags().Lookup("dynamic-config-value").Value.(dynamicConfigValues)
+	if !ok {
+		t.Fatalf("expected dynamic-config-value flag to hold a dynamicConfigValues")
+	}
+	if dynamicValue["foo"] != "bar" {
+		t.Errorf("expected foo=bar to be parsed into the Config's DynamicConfigValue, got: %v", dynamicValue)
+	}
+}
+
+func TestNewRootCmdRegistersExpectedSubcommands(t *testing.T) {
+	root := newRootCmd()
+	for _, name := range []string{"start", "status", "stop", "extract"} {
+		if _, _, err := root.Find([]string{name}); err != nil {
+			t.Errorf("expected subcommand %q to be registered: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+//go:build !headless
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/temporalio/temporalite"
+	uiserver "github.com/temporalio/ui-server/v2/server"
+	uiconfig "github.com/temporalio/ui-server/v2/server/config"
+	"temporal-service/i18n"
+)
+
+// newTemporalBackend returns the in-process backend. It pulls in the
+// temporalite dev-server and, unless headless, the Temporal web UI - the
+// reason those dependencies are gated behind the headless build tag.
+func newTemporalBackend(cfg *Config) temporalBackend {
+	return &embeddedBackend{}
+}
+
+// embeddedBackend runs Temporal's frontend/history/matching/worker services
+// in-process using temporalite, instead of shelling out to the temporal CLI.
+type embeddedBackend struct {
+	server   *temporalite.Server
+	uiServer *uiserver.Server
+	liveness *Supervisor
+}
+
+func (b *embeddedBackend) Start(cfg *Config, ports *PortConfig) error {
+	liveness, err := NewSupervisor(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to set up liveness tracking: %w", err)
+	}
+
+	if existing, ok := liveness.readPortsFile(); ok && isProcessAlive(existing.PID) && isTemporalServerRunning(existing.TemporalPort) {
+		log.Printf(i18n.T("TemporalServerAlreadyRunningOnPort"), existing.TemporalPort)
+		ports.TemporalPort = existing.TemporalPort
+		ports.UIPort = existing.UIPort
+		ports.releaseTemporalListeners()
+		b.liveness = liveness
+		return nil
+	}
+
+	// We only reserved these ports to keep them free; temporalite binds its
+	// own listeners, so release ours right before it does.
+	ports.releaseTemporalListeners()
+
+	if len(cfg.DynamicConfigValue) > 0 {
+		log.Printf("Warning: --dynamic-config-value is not supported by the embedded backend (temporalite takes typed dynamicconfig.Key constants, not arbitrary key=val strings); ignoring %d override(s). Use a headless build if you need them.", len(cfg.DynamicConfigValue))
+	}
+
+	opts := []temporalite.ServerOption{
+		temporalite.WithNamespaces(cfg.Namespace),
+		temporalite.WithFrontendPort(ports.TemporalPort),
+	}
+	if cfg.Ephemeral {
+		opts = append(opts, temporalite.WithPersistenceDisabled())
+	} else {
+		opts = append(opts, temporalite.WithDatabaseFilePath(cfg.DBFilename))
+	}
+
+	srv, err := temporalite.NewServer(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to construct embedded Temporal server: %w", err)
+	}
+	b.server = srv
+
+	if err := srv.Start(); err != nil {
+		return fmt.Errorf("failed to start embedded Temporal server: %w", err)
+	}
+
+	// Temporal runs in this process rather than as a separate child, so
+	// record our own PID: `temporal-service status`/`stop`, run from a later
+	// invocation, read this the same way they'd read a cliBackend's child
+	// PID, and stopping us is exactly the SIGTERM path runStart already
+	// shuts down gracefully on.
+	if err := liveness.RecordLiveness(os.Getpid(), ports); err != nil {
+		log.Printf("Warning: failed to record supervisor state files: %v", err)
+	}
+	b.liveness = liveness
+
+	if cfg.Headless {
+		return nil
+	}
+
+	b.uiServer = uiserver.NewServer(uiserver.WithConfigProvider(&uiconfig.Config{
+		TemporalGRPCAddress: fmt.Sprintf("127.0.0.1:%d", ports.TemporalPort),
+		Host:                "127.0.0.1",
+		Port:                ports.UIPort,
+	}))
+	go func() {
+		if err := b.uiServer.Start(); err != nil {
+			log.Printf(i18n.T("FailedToStartTemporalServer"), err)
+		}
+	}()
+
+	return nil
+}
+
+// Supervisor exposes the Supervisor tracking this backend's own liveness, so
+// the HTTP layer can mount /supervisor endpoints against it just as it does
+// for cliBackend.
+func (b *embeddedBackend) Supervisor() *Supervisor {
+	return b.liveness
+}
+
+func (b *embeddedBackend) Stop() error {
+	if b.liveness != nil {
+		b.liveness.ClearLiveness()
+	}
+	if b.uiServer != nil {
+		b.uiServer.Stop()
+	}
+	if b.server != nil {
+		return b.server.Stop()
+	}
+	return nil
+}
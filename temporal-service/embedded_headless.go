@@ -0,0 +1,10 @@
+//go:build headless
+
+package main
+
+// newTemporalBackend always returns nil in headless builds: the embedded
+// backend pulls in the Temporal web UI dependency that headless builds are
+// built to exclude, so NewTemporalService falls back to cliBackend instead.
+func newTemporalBackend(cfg *Config) temporalBackend {
+	return nil
+}
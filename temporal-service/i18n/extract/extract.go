@@ -0,0 +1,119 @@
+// Package extract walks Go source looking for i18n.T/i18n.Tf/i18n.Tn calls
+// and regenerates messages/en.json, the source of truth every other
+// locale's translations are built from.
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// messageIDCallees are the i18n package functions whose first argument is a
+// message ID literal.
+var messageIDCallees = map[string]bool{"T": true, "Tf": true, "Tn": true}
+
+// MessageIDs walks every .go file under srcDir (skipping tests) and returns
+// the sorted set of message IDs passed as string literals to
+// i18n.T/i18n.Tf/i18n.Tn.
+func MessageIDs(srcDir string) ([]string, error) {
+	ids := map[string]bool{}
+
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			id, ok := messageIDFromCall(n)
+			if ok {
+				ids[id] = true
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// messageIDFromCall reports the message ID literal if n is a call to
+// i18n.T/i18n.Tf/i18n.Tn with a string literal as its first argument.
+func messageIDFromCall(n ast.Node) (string, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !messageIDCallees[sel.Sel.Name] {
+		return "", false
+	}
+
+	pkgIdent, ok := sel.X.(*ast.Ident)
+	if !ok || pkgIdent.Name != "i18n" {
+		return "", false
+	}
+
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	id, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// UpdateMessageFile regenerates messagesPath from ids: IDs that already have
+// a translation there keep it, and new IDs are added with a TODO
+// placeholder. IDs no longer referenced in source are dropped.
+func UpdateMessageFile(messagesPath string, ids []string) error {
+	existing := map[string]interface{}{}
+	if data, err := os.ReadFile(messagesPath); err == nil {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("failed to parse existing %s: %w", messagesPath, err)
+		}
+	}
+
+	merged := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		if value, ok := existing[id]; ok {
+			merged[id] = value
+			continue
+		}
+		merged[id] = fmt.Sprintf("TODO: %s", id)
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", messagesPath, err)
+	}
+	return os.WriteFile(messagesPath, append(data, '\n'), 0o644)
+}
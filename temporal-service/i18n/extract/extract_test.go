@@ -0,0 +1,67 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMessageIDs(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+import "temporal-service/i18n"
+
+func run() {
+	i18n.T("Hello")
+	i18n.Tf("World", "x")
+	i18n.Tn("Count", 2, nil)
+	i18n.T("Hello") // duplicate, should only appear once
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	ids, err := MessageIDs(dir)
+	if err != nil {
+		t.Fatalf("MessageIDs failed: %v", err)
+	}
+
+	want := []string{"Count", "Hello", "World"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i, id := range want {
+		if ids[i] != id {
+			t.Errorf("expected %v, got %v", want, ids)
+			break
+		}
+	}
+}
+
+func TestUpdateMessageFileKeepsExistingTranslations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "en.json")
+	if err := os.WriteFile(path, []byte(`{"Hello": "Hi there"}`), 0o644); err != nil {
+		t.Fatalf("failed to seed message file: %v", err)
+	}
+
+	if err := UpdateMessageFile(path, []string{"Hello", "NewMessage"}); err != nil {
+		t.Fatalf("UpdateMessageFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read updated message file: %v", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, `"Hello": "Hi there"`) {
+		t.Errorf("expected existing translation to be preserved, got: %s", content)
+	}
+	if !strings.Contains(content, `"NewMessage": "TODO: NewMessage"`) {
+		t.Errorf("expected new message to get a TODO placeholder, got: %s", content)
+	}
+}
@@ -24,7 +24,7 @@ var Localizer *i18n.Localizer
 const DefaultLocale = "en"
 
 // SupportedLocales lists all supported languages
-var SupportedLocales = []string{"en", "pt-BR"}
+var SupportedLocales = []string{"en", "pt-BR", "es", "ja", "zh-CN"}
 
 // Init initializes the i18n system with the specified locale
 func Init(locale string) error {
@@ -71,7 +71,7 @@ func loadMessageFiles() error {
 		if err != nil {
 			return fmt.Errorf("failed to parse message file %s: %w", entry.Name(), err)
 		}
-		
+
 		// Add messages to bundle
 		for _, message := range messageFile.Messages {
 			Bundle.AddMessages(messageFile.Tag, message)
@@ -91,39 +91,113 @@ func isValidLocale(locale string) bool {
 	return false
 }
 
-// GetLocale returns the current locale from environment variable or default
+// GetLocale returns the current locale, preferring GOOSE_LANG (an explicit
+// override for this application) and otherwise resolving the user's system
+// locale from LC_ALL/LANG against SupportedLocales.
 func GetLocale() string {
-	if locale := os.Getenv("GOOSE_LANG"); locale != "" {
-		if isValidLocale(locale) {
-			return locale
+	if locale := os.Getenv("GOOSE_LANG"); locale != "" && isValidLocale(locale) {
+		return locale
+	}
+
+	matcher := language.NewMatcher(supportedLanguageTags())
+	for _, envVar := range []string{"LC_ALL", "LANG"} {
+		raw := os.Getenv(envVar)
+		if raw == "" || raw == "C" || raw == "POSIX" {
+			continue
+		}
+		tag := parseLocaleEnv(raw)
+		if tag == language.Und {
+			continue
+		}
+		_, index, confidence := matcher.Match(tag)
+		if confidence >= language.Low {
+			return SupportedLocales[index]
 		}
 	}
+
 	return DefaultLocale
 }
 
-// T returns a localized message for the given message ID
+// parseLocaleEnv turns a POSIX-style locale value (e.g. "pt_BR.UTF-8") into a
+// BCP 47 language tag, returning language.Und if it can't be parsed.
+func parseLocaleEnv(raw string) language.Tag {
+	value := raw
+	if idx := strings.IndexAny(value, ".@"); idx >= 0 {
+		value = value[:idx]
+	}
+	value = strings.ReplaceAll(value, "_", "-")
+
+	tag, err := language.Parse(value)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
+
+// supportedLanguageTags parses SupportedLocales into language.Tags, in the
+// same order, for use with a language.Matcher.
+func supportedLanguageTags() []language.Tag {
+	tags := make([]language.Tag, len(SupportedLocales))
+	for i, locale := range SupportedLocales {
+		tags[i] = language.MustParse(locale)
+	}
+	return tags
+}
+
+// TemplateData supplies {{.Field}}-style placeholders for a localized
+// message, per go-i18n's message templates.
+type TemplateData map[string]interface{}
+
+// T returns a localized message for the given message ID. A TemplateData
+// argument fills {{.Field}} placeholders in the message; any other args are
+// applied with fmt.Sprintf, for the %s/%d-style messages used throughout
+// this package.
 func T(messageID string, args ...interface{}) string {
+	return localize(messageID, nil, args...)
+}
+
+// Tf is a deprecated alias for T, kept for callers that haven't moved their
+// positional %s/%d placeholders over to TemplateData.
+func Tf(messageID string, args ...interface{}) string {
+	return T(messageID, args...)
+}
+
+// Tn returns a localized message for messageID, selecting the CLDR plural
+// form (one/other/...) for the active locale based on pluralCount, with
+// data filling any {{.Field}} placeholders.
+func Tn(messageID string, pluralCount int, data TemplateData) string {
+	return localize(messageID, pluralCount, data)
+}
+
+// localize resolves messageID through the active Localizer, applying
+// pluralCount (nil selects no plural form) and any TemplateData/sprintf args
+// found in args.
+func localize(messageID string, pluralCount interface{}, args ...interface{}) string {
 	if Localizer == nil {
 		// Fallback to English if i18n not initialized
 		return messageID
 	}
 
-	message, err := Localizer.Localize(&i18n.LocalizeConfig{
-		MessageID: messageID,
-	})
+	cfg := &i18n.LocalizeConfig{MessageID: messageID, PluralCount: pluralCount}
+
+	var sprintfArgs []interface{}
+	for _, arg := range args {
+		if data, ok := arg.(TemplateData); ok {
+			cfg.TemplateData = map[string]interface{}(data)
+			continue
+		}
+		sprintfArgs = append(sprintfArgs, arg)
+	}
+
+	message, err := Localizer.Localize(cfg)
 	if err != nil {
 		// Fallback to message ID if localization fails
 		return messageID
 	}
 
-	if len(args) > 0 {
-		message = fmt.Sprintf(message, args...)
+	if len(sprintfArgs) > 0 {
+		message = fmt.Sprintf(message, sprintfArgs...)
 	}
 
 	return message
 }
-
-// Tf returns a localized message with formatting (like fmt.Sprintf)
-func Tf(messageID string, args ...interface{}) string {
-	return T(messageID, args...)
-}
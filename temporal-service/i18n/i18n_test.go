@@ -91,3 +91,46 @@ func TestGetLocale(t *testing.T) {
 		t.Errorf("Expected default locale %s, got %s", DefaultLocale, locale)
 	}
 }
+
+func TestGetLocaleFromLANG(t *testing.T) {
+	t.Setenv("GOOSE_LANG", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+
+	if locale := GetLocale(); locale != "pt-BR" {
+		t.Errorf("Expected LANG=pt_BR.UTF-8 to resolve to pt-BR, got %s", locale)
+	}
+}
+
+func TestGetLocaleGooseLangTakesPrecedence(t *testing.T) {
+	t.Setenv("GOOSE_LANG", "es")
+	t.Setenv("LANG", "ja_JP.UTF-8")
+
+	if locale := GetLocale(); locale != "es" {
+		t.Errorf("Expected GOOSE_LANG to take precedence over LANG, got %s", locale)
+	}
+}
+
+func TestTnPluralization(t *testing.T) {
+	if err := Init("en"); err != nil {
+		t.Fatalf("Failed to initialize i18n: %v", err)
+	}
+
+	one := Tn("ManagedRecipesCount", 1, TemplateData{"Count": 1})
+	if one != "1 managed recipe" {
+		t.Errorf("Expected singular form, got: %s", one)
+	}
+
+	other := Tn("ManagedRecipesCount", 3, TemplateData{"Count": 3})
+	if other != "3 managed recipes" {
+		t.Errorf("Expected plural form, got: %s", other)
+	}
+
+	// A count of zero must still be passed through as an explicit plural
+	// count rather than treated as "no count given" (English's CLDR plural
+	// rules resolve 0 to the "other" category).
+	zero := Tn("ManagedRecipesCount", 0, TemplateData{"Count": 0})
+	if zero != "0 managed recipes" {
+		t.Errorf("Expected plural form for zero count, got: %s", zero)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -27,11 +26,36 @@ const (
 	Namespace     = "default"
 )
 
-// PortConfig holds the port configuration for Temporal services
+// PortConfig holds the port configuration for Temporal services.
+//
+// HTTPPort's listener is bound for the lifetime of the process and handed
+// directly to http.Server.Serve, so the port we report is guaranteed to be
+// the port we're actually listening on. temporalListener/uiListener are
+// probe listeners: they reserve the port we intend to hand to the Temporal
+// CLI, and are only released in releaseTemporalListeners, right before we
+// exec it, to keep the bind-time race as small as possible.
 type PortConfig struct {
 	TemporalPort int // Main Temporal server port
 	UIPort       int // Temporal UI port
 	HTTPPort     int // HTTP API port
+
+	HTTPListener net.Listener
+
+	temporalListener net.Listener
+	uiListener       net.Listener
+}
+
+// releaseTemporalListeners closes the probe listeners held for the Temporal
+// CLI's ports. Call this immediately before exec.Start.
+func (p *PortConfig) releaseTemporalListeners() {
+	if p.temporalListener != nil {
+		p.temporalListener.Close()
+		p.temporalListener = nil
+	}
+	if p.uiListener != nil {
+		p.uiListener.Close()
+		p.uiListener = nil
+	}
 }
 
 // getManagedRecipesDir returns the proper directory for storing managed recipes
@@ -76,49 +100,64 @@ func getManagedRecipesDir() (string, error) {
 	return baseDir, nil
 }
 
-// findAvailablePort finds an available port starting from the given port
-func findAvailablePort(startPort int) (int, error) {
+// findAvailableListener binds to the first available port starting from the
+// given port and returns the live listener. Unlike probing with Listen+Close,
+// the caller owns the listener and nothing else can bind the port out from
+// under it until the caller releases it.
+func findAvailableListener(startPort int) (net.Listener, error) {
 	for port := startPort; port < startPort+100; port++ {
 		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 		if err == nil {
-			ln.Close()
-			return port, nil
+			return ln, nil
 		}
 	}
-	return 0, fmt.Errorf("no available port found starting from %d", startPort)
+	return nil, fmt.Errorf("no available port found starting from %d", startPort)
 }
 
-// findAvailablePorts finds available ports for all Temporal services
-func findAvailablePorts() (*PortConfig, error) {
-	// Try to find available ports starting from preferred defaults
-	temporalPort, err := findAvailablePort(7233)
+// findAvailablePorts finds available ports for all Temporal services and
+// holds their listeners open in the returned PortConfig. The HTTP listener
+// stays open for the life of the process; the Temporal/UI probe listeners
+// are released by releaseTemporalListeners right before we exec the CLI.
+// A zero value in cfg.TemporalPort/UIPort/HTTPPort means "pick the first
+// available port starting from the usual default".
+func findAvailablePorts(cfg *Config) (*PortConfig, error) {
+	temporalStart := cfg.TemporalPort
+	if temporalStart == 0 {
+		temporalStart = 7233
+	}
+	temporalListener, err := findAvailableListener(temporalStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find available port for Temporal server: %w", err)
 	}
 
-	uiPort, err := findAvailablePort(8233)
+	uiStart := cfg.UIPort
+	if uiStart == 0 {
+		uiStart = 8233
+	}
+	uiListener, err := findAvailableListener(uiStart)
 	if err != nil {
+		temporalListener.Close()
 		return nil, fmt.Errorf("failed to find available port for Temporal UI: %w", err)
 	}
 
-	// For HTTP port, check environment variable first
-	httpPort := 8080
-	if portEnv := os.Getenv("PORT"); portEnv != "" {
-		if parsed, err := strconv.Atoi(portEnv); err == nil {
-			httpPort = parsed
-		}
+	httpStart := cfg.HTTPPort
+	if httpStart == 0 {
+		httpStart = 8080
 	}
-
-	// Verify HTTP port is available, find alternative if not
-	finalHTTPPort, err := findAvailablePort(httpPort)
+	httpListener, err := findAvailableListener(httpStart)
 	if err != nil {
+		temporalListener.Close()
+		uiListener.Close()
 		return nil, fmt.Errorf("failed to find available port for HTTP server: %w", err)
 	}
 
 	return &PortConfig{
-		TemporalPort: temporalPort,
-		UIPort:       uiPort,
-		HTTPPort:     finalHTTPPort,
+		TemporalPort:     temporalListener.Addr().(*net.TCPAddr).Port,
+		UIPort:           uiListener.Addr().(*net.TCPAddr).Port,
+		HTTPPort:         httpListener.Addr().(*net.TCPAddr).Port,
+		HTTPListener:     httpListener,
+		temporalListener: temporalListener,
+		uiListener:       uiListener,
 	}, nil
 }
 
@@ -256,106 +295,27 @@ func getExistingTemporalCLIFrom(possiblePaths []string) (string, error) {
 	return "", fmt.Errorf("temporal CLI not found in PATH or any of the expected locations: %v", possiblePaths)
 }
 
-// ensureTemporalServerRunning checks if Temporal server is running and starts it if needed
-func ensureTemporalServerRunning(ports *PortConfig) error {
-	log.Println("Checking if Temporal server is running...")
+// Starting, supervising, and stopping the temporal CLI child process itself
+// lives in Supervisor (see supervisor.go), which cliBackend delegates to.
 
-	// Check if Temporal server is already running by trying to connect
-	if isTemporalServerRunning(ports.TemporalPort) {
-		log.Printf(i18n.T("TemporalServerAlreadyRunningOnPort"), ports.TemporalPort)
-		return nil
-	}
-
-	log.Printf(i18n.T("TemporalServerNotRunningAttemptingToStart"), ports.TemporalPort)
-
-	// Find the temporal CLI binary
-	temporalCmd, err := findTemporalCLI()
-	if err != nil {
-		log.Printf(i18n.T("CouldNotFindTemporalCLI"), err)
-		return fmt.Errorf("could not find temporal CLI: %w", err)
-	}
-
-	log.Printf(i18n.T("UsingTemporalCLIAt"), temporalCmd)
-
-	// Start Temporal server in background
-	args := []string{"server", "start-dev",
-		"--db-filename", "temporal.db",
-		"--port", strconv.Itoa(ports.TemporalPort),
-		"--ui-port", strconv.Itoa(ports.UIPort),
-		"--log-level", "warn"}
-
-	log.Printf(i18n.T("StartingTemporalServerWithCommand"), temporalCmd, args)
-
-	cmd := exec.Command(temporalCmd, args...)
-
-	// Properly detach the process so it survives when the parent exits
-	configureSysProcAttr(cmd)
-
-	// Redirect stdin/stdout/stderr to avoid hanging
-	cmd.Stdin = nil
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-
-	// Start the process
-	if err := cmd.Start(); err != nil {
-		log.Printf(i18n.T("FailedToStartTemporalServer"), err)
-		return fmt.Errorf("failed to start Temporal server: %w", err)
-	}
-
-	log.Printf(i18n.T("TemporalServerStartedWithPID"),
-		cmd.Process.Pid, ports.TemporalPort, ports.UIPort)
-
-	// Wait for server to be ready (with timeout)
-	log.Println("Waiting for Temporal server to be ready...")
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	attemptCount := 0
-	for {
-		select {
-		case <-timeout:
-			log.Printf(i18n.T("TimeoutWaitingForTemporalServerToStart"), attemptCount)
-			return fmt.Errorf("timeout waiting for Temporal server to start")
-		case <-ticker.C:
-			attemptCount++
-			log.Printf(i18n.T("CheckingIfTemporalServerIsReady"), attemptCount)
-			if isTemporalServerRunning(ports.TemporalPort) {
-				log.Printf(i18n.T("TemporalServerIsNowReadyOnPort"), ports.TemporalPort)
-				return nil
-			} else {
-				log.Printf(i18n.T("TemporalServerNotReadyYet"), attemptCount)
-			}
-		}
-	}
-}
-
-func main() {
-	// Parse command line arguments for language
-	var lang string
-	if len(os.Args) > 1 && os.Args[1] == "--lang" && len(os.Args) > 2 {
-		lang = os.Args[2]
-		// Remove the --lang flag and value from os.Args
-		os.Args = append(os.Args[:1], os.Args[3:]...)
-	} else {
-		lang = i18n.GetLocale()
-	}
-	
+// runStart builds a Temporal service from cfg and blocks serving the HTTP
+// API until a shutdown signal arrives.
+func runStart(cfg *Config) error {
 	// Initialize i18n system
-	if err := i18n.Init(lang); err != nil {
+	if err := i18n.Init(cfg.Lang); err != nil {
 		log.Printf("Warning: Failed to initialize i18n system: %v, falling back to English", err)
 	}
-	
-	log.Printf("Using language: %s", lang)
+
+	log.Printf("Using language: %s", cfg.Lang)
 	log.Println(i18n.T("StartingTemporalService"))
 	log.Printf(i18n.T("RuntimeOS"), runtime.GOOS)
 	log.Printf(i18n.T("RuntimeARCH"), runtime.GOARCH)
-	
+
 	// Log current working directory for debugging
 	if cwd, err := os.Getwd(); err == nil {
 		log.Printf(i18n.T("CurrentWorkingDirectory"), cwd)
 	}
-	
+
 	// Log environment variables that might affect behavior
 	if port := os.Getenv("PORT"); port != "" {
 		log.Printf(i18n.T("PortEnvironmentVariable"), port)
@@ -369,10 +329,9 @@ func main() {
 
 	// Create Temporal service (this will find available ports automatically)
 	log.Println(i18n.T("CreatingTemporalService"))
-	service, err := NewTemporalService()
+	service, err := NewTemporalService(cfg)
 	if err != nil {
-		log.Printf(i18n.T("FailedToCreateTemporalService"), err)
-		log.Fatalf(i18n.T("FailedToCreateTemporalService"), err)
+		return fmt.Errorf(i18n.T("FailedToCreateTemporalService"), err)
 	}
 	log.Println(i18n.T("TemporalServiceCreatedSuccessfully"))
 
@@ -389,9 +348,11 @@ func main() {
 	mux.HandleFunc("/jobs", service.handleJobs)
 	mux.HandleFunc("/health", service.handleHealth)
 	mux.HandleFunc("/ports", service.handlePorts)
+	if supervisor := service.Supervisor(); supervisor != nil {
+		registerSupervisorHandlers(mux, supervisor)
+	}
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", httpPort),
 		Handler: mux,
 	}
 
@@ -422,7 +383,43 @@ func main() {
 	log.Printf(i18n.T("JobsEndpoint"), httpPort)
 	log.Printf(i18n.T("PortsEndpoint"), httpPort)
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf(i18n.T("HTTPServerFailed"), err)
+	// The listener was bound back when ports were chosen, so there's no
+	// window between "we decided on this port" and "we're listening on it".
+	if err := server.Serve(service.GetHTTPListener()); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf(i18n.T("HTTPServerFailed"), err)
+	}
+	return nil
+}
+
+// runStatus reports whether a supervised Temporal service is running,
+// reading its PID/port file so it works across invocations.
+func runStatus(cfg *Config) error {
+	supervisor, err := NewSupervisor(cfg)
+	if err != nil {
+		return err
+	}
+
+	status := supervisor.Status()
+	if !status.Running {
+		fmt.Println("Temporal server is not running")
+		return nil
+	}
+	fmt.Printf("Temporal server is running (PID %d, Temporal port %d, UI port %d)\n",
+		status.PID, status.TemporalPort, status.UIPort)
+	return nil
+}
+
+// runStop terminates a previously started, supervised Temporal service.
+func runStop(cfg *Config) error {
+	supervisor, err := NewSupervisor(cfg)
+	if err != nil {
+		return err
+	}
+	return supervisor.StopExternal()
+}
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestFindAvailableListenerReturnsLiveListener(t *testing.T) {
+	ln, err := findAvailableListener(0)
+	if err != nil {
+		t.Fatalf("findAvailableListener failed: %v", err)
+	}
+	defer ln.Close()
+
+	addr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		t.Fatalf("expected a *net.TCPAddr, got %T", ln.Addr())
+	}
+	if addr.Port == 0 {
+		t.Error("expected a concrete port to be assigned, got 0")
+	}
+
+	// The port is still ours: nothing else can bind it out from under us.
+	if _, err := net.Listen("tcp", addr.String()); err == nil {
+		t.Error("expected the port to still be held by the first listener")
+	}
+}
+
+func TestFindAvailablePortsHoldsListenersUntilReleased(t *testing.T) {
+	cfg := &Config{}
+	ports, err := findAvailablePorts(cfg)
+	if err != nil {
+		t.Fatalf("findAvailablePorts failed: %v", err)
+	}
+	defer ports.HTTPListener.Close()
+
+	if ports.TemporalPort == 0 || ports.UIPort == 0 || ports.HTTPPort == 0 {
+		t.Errorf("expected all ports to be assigned, got: %+v", ports)
+	}
+	if ports.temporalListener == nil || ports.uiListener == nil {
+		t.Error("expected probe listeners to be held open")
+	}
+
+	ports.releaseTemporalListeners()
+
+	if ports.temporalListener != nil || ports.uiListener != nil {
+		t.Error("expected releaseTemporalListeners to clear the probe listeners")
+	}
+
+	// Released ports should be bindable again.
+	ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(ports.TemporalPort)))
+	if err != nil {
+		t.Errorf("expected Temporal port to be free after release, got: %v", err)
+	} else {
+		ln.Close()
+	}
+
+	// The HTTP listener is unaffected by releaseTemporalListeners.
+	if ports.HTTPListener == nil {
+		t.Error("expected HTTPListener to remain open")
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"temporal-service/i18n"
+)
+
+const (
+	supervisorPIDFilename   = "temporal-service.pid"
+	supervisorPortsFilename = "temporal-service.ports.json"
+	supervisorLogFilename   = "temporal-service.log"
+
+	maxLogFileBytes = 5 * 1024 * 1024 // rotate once the log file passes this size
+	maxLogBackups   = 3
+
+	restartInitialBackoff = 1 * time.Second
+	restartMaxBackoff     = 30 * time.Second
+)
+
+// supervisorPorts is the on-disk record of the ports a supervised Temporal
+// server is listening on, written alongside its PID file so a later
+// invocation can reattach to it.
+type supervisorPorts struct {
+	PID          int `json:"pid"`
+	TemporalPort int `json:"temporalPort"`
+	UIPort       int `json:"uiPort"`
+}
+
+// Supervisor owns the lifecycle of a CLI-spawned Temporal server: it writes
+// a PID + port file other invocations can reattach to, pipes the child's
+// stdout/stderr through a rotating log file, and restarts it with
+// exponential backoff if it exits unexpectedly.
+type Supervisor struct {
+	cfg      *Config
+	stateDir string
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	ports     *PortConfig
+	log       *rotatingLogFile
+	stopping  bool
+	backoff   time.Duration
+	restartWG sync.WaitGroup
+	stopCh    chan struct{}
+}
+
+// NewSupervisor creates a Supervisor whose state files live alongside the
+// managed recipes directory.
+func NewSupervisor(cfg *Config) (*Supervisor, error) {
+	stateDir, err := supervisorStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Supervisor{
+		cfg:      cfg,
+		stateDir: stateDir,
+		backoff:  restartInitialBackoff,
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// supervisorStateDir returns the directory for supervisor state (PID file,
+// port file, rotated logs), next to the managed recipes directory.
+func supervisorStateDir() (string, error) {
+	recipesDir, err := getManagedRecipesDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(recipesDir), "supervisor")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create supervisor state directory: %w", err)
+	}
+	return dir, nil
+}
+
+func (s *Supervisor) pidFilePath() string {
+	return filepath.Join(s.stateDir, supervisorPIDFilename)
+}
+
+func (s *Supervisor) portsFilePath() string {
+	return filepath.Join(s.stateDir, supervisorPortsFilename)
+}
+
+func (s *Supervisor) logFilePath() string {
+	return filepath.Join(s.stateDir, supervisorLogFilename)
+}
+
+// Start reattaches to a previously spawned server recorded in the port
+// file if it's still alive and responding, or spawns a fresh one and begins
+// supervising it.
+func (s *Supervisor) Start(ports *PortConfig) error {
+	s.ports = ports
+
+	if existing, ok := s.readPortsFile(); ok && isProcessAlive(existing.PID) && isTemporalServerRunning(existing.TemporalPort) {
+		log.Printf(i18n.T("TemporalServerAlreadyRunningOnPort"), existing.TemporalPort)
+		ports.TemporalPort = existing.TemporalPort
+		ports.UIPort = existing.UIPort
+		ports.releaseTemporalListeners()
+		return nil
+	}
+
+	// No other reattach path is reachable here: ports.TemporalPort's
+	// listener is still held open by us (released in spawn(), right before
+	// we exec the CLI), so nothing else could be listening on it yet.
+
+	log.Printf(i18n.T("TemporalServerNotRunningAttemptingToStart"), ports.TemporalPort)
+	return s.spawn()
+}
+
+// spawn starts a new temporal CLI child process and begins supervising it.
+func (s *Supervisor) spawn() error {
+	temporalCmd, err := findTemporalCLI()
+	if err != nil {
+		log.Printf(i18n.T("CouldNotFindTemporalCLI"), err)
+		return fmt.Errorf("could not find temporal CLI: %w", err)
+	}
+	log.Printf(i18n.T("UsingTemporalCLIAt"), temporalCmd)
+
+	logFile, err := newRotatingLogFile(s.logFilePath())
+	if err != nil {
+		return fmt.Errorf("failed to open supervisor log file: %w", err)
+	}
+
+	args := []string{"server", "start-dev"}
+	if s.cfg.Ephemeral {
+		args = append(args, "--ephemeral")
+	} else {
+		args = append(args, "--db-filename", s.cfg.DBFilename)
+	}
+	args = append(args,
+		"--port", fmt.Sprintf("%d", s.ports.TemporalPort),
+		"--ui-port", fmt.Sprintf("%d", s.ports.UIPort),
+		"--log-format", s.cfg.LogFormat,
+		"--namespace", s.cfg.Namespace)
+	if s.cfg.Headless {
+		args = append(args, "--headless")
+	}
+
+	// Sort keys for a deterministic command line across runs.
+	keys := make([]string, 0, len(s.cfg.DynamicConfigValue))
+	for key := range s.cfg.DynamicConfigValue {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--dynamic-config-value", fmt.Sprintf("%s=%s", key, s.cfg.DynamicConfigValue[key]))
+	}
+
+	log.Printf(i18n.T("StartingTemporalServerWithCommand"), temporalCmd, args)
+
+	cmd := exec.Command(temporalCmd, args...)
+	configureSysProcAttr(cmd)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	s.ports.releaseTemporalListeners()
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		log.Printf(i18n.T("FailedToStartTemporalServer"), err)
+		return fmt.Errorf("failed to start Temporal server: %w", err)
+	}
+
+	log.Printf(i18n.T("TemporalServerStartedWithPID"), cmd.Process.Pid, s.ports.TemporalPort, s.ports.UIPort)
+
+	s.mu.Lock()
+	s.cmd = cmd
+	s.log = logFile
+	s.mu.Unlock()
+
+	if err := s.writeStateFiles(cmd.Process.Pid); err != nil {
+		log.Printf("Warning: failed to write supervisor state files: %v", err)
+	}
+
+	s.restartWG.Add(1)
+	go s.superviseRestarts()
+
+	return waitUntilReady(s.ports.TemporalPort)
+}
+
+// superviseRestarts waits for the child to exit and, unless Stop was called,
+// respawns it with exponential backoff.
+func (s *Supervisor) superviseRestarts() {
+	defer s.restartWG.Done()
+
+	for {
+		s.mu.Lock()
+		cmd := s.cmd
+		s.mu.Unlock()
+		if cmd == nil {
+			return
+		}
+
+		err := cmd.Wait()
+
+		s.mu.Lock()
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping {
+			return
+		}
+
+		log.Printf("Temporal server (PID %d) exited unexpectedly: %v; restarting in %s", cmd.Process.Pid, err, s.backoff)
+
+		// Wake immediately if Stop is called while we're backing off,
+		// rather than spawning a replacement Stop no longer knows about.
+		select {
+		case <-time.After(s.backoff):
+		case <-s.stopCh:
+			return
+		}
+		s.backoff *= 2
+		if s.backoff > restartMaxBackoff {
+			s.backoff = restartMaxBackoff
+		}
+
+		s.mu.Lock()
+		stopping = s.stopping
+		s.mu.Unlock()
+		if stopping {
+			return
+		}
+
+		if err := s.spawn(); err != nil {
+			log.Printf("Failed to restart Temporal server: %v", err)
+			return
+		}
+		return // spawn() started its own superviseRestarts goroutine
+	}
+}
+
+// Stop gracefully terminates the supervised Temporal server, if any.
+func (s *Supervisor) Stop() error {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	cmd := s.cmd
+	logFile := s.log
+	s.mu.Unlock()
+
+	close(s.stopCh)
+
+	if cmd != nil && cmd.Process != nil {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			cmd.Process.Kill()
+		}
+	}
+	s.restartWG.Wait()
+
+	if logFile != nil {
+		logFile.Close()
+	}
+	os.Remove(s.pidFilePath())
+	os.Remove(s.portsFilePath())
+	return nil
+}
+
+// Status describes whether the supervised server is currently running.
+type Status struct {
+	Running      bool `json:"running"`
+	PID          int  `json:"pid,omitempty"`
+	TemporalPort int  `json:"temporalPort,omitempty"`
+	UIPort       int  `json:"uiPort,omitempty"`
+}
+
+// Status reports the current state of the supervised server, reading the
+// port file so it also works for reattached servers from a prior process.
+func (s *Supervisor) Status() Status {
+	ports, ok := s.readPortsFile()
+	if !ok || !isProcessAlive(ports.PID) {
+		return Status{Running: false}
+	}
+	return Status{
+		Running:      true,
+		PID:          ports.PID,
+		TemporalPort: ports.TemporalPort,
+		UIPort:       ports.UIPort,
+	}
+}
+
+// StopExternal terminates a previously spawned, detached supervised server
+// by reading its PID from disk. Unlike Stop, it has no in-memory handle to
+// the child - this is what `temporal-service stop` uses from a fresh
+// process to reach a server started by an earlier invocation.
+func (s *Supervisor) StopExternal() error {
+	ports, ok := s.readPortsFile()
+	if !ok {
+		return fmt.Errorf("no supervised Temporal server found")
+	}
+	if !isProcessAlive(ports.PID) {
+		os.Remove(s.pidFilePath())
+		os.Remove(s.portsFilePath())
+		return fmt.Errorf("supervised Temporal server (PID %d) is not running", ports.PID)
+	}
+
+	process, err := os.FindProcess(ports.PID)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop Temporal server (PID %d): %w", ports.PID, err)
+	}
+
+	os.Remove(s.pidFilePath())
+	os.Remove(s.portsFilePath())
+	return nil
+}
+
+func (s *Supervisor) writeStateFiles(pid int) error {
+	if err := os.WriteFile(s.pidFilePath(), []byte(fmt.Sprintf("%d", pid)), 0o644); err != nil {
+		return err
+	}
+	data, err := json.Marshal(supervisorPorts{
+		PID:          pid,
+		TemporalPort: s.ports.TemporalPort,
+		UIPort:       s.ports.UIPort,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.portsFilePath(), data, 0o644)
+}
+
+// RecordLiveness writes the PID/port state files for a server that isn't a
+// supervised child process, so Status/StopExternal can find it from a later
+// invocation. This is what embeddedBackend uses: temporalite runs in this
+// same process rather than as a separate child, so there's nothing to spawn
+// or restart-supervise, but the PID we record is our own - stopping it is
+// exactly the SIGTERM path runStart already shuts down gracefully on.
+func (s *Supervisor) RecordLiveness(pid int, ports *PortConfig) error {
+	s.ports = ports
+	return s.writeStateFiles(pid)
+}
+
+// ClearLiveness removes the state files written by RecordLiveness.
+func (s *Supervisor) ClearLiveness() {
+	os.Remove(s.pidFilePath())
+	os.Remove(s.portsFilePath())
+}
+
+func (s *Supervisor) readPortsFile() (supervisorPorts, bool) {
+	data, err := os.ReadFile(s.portsFilePath())
+	if err != nil {
+		return supervisorPorts{}, false
+	}
+	var ports supervisorPorts
+	if err := json.Unmarshal(data, &ports); err != nil {
+		return supervisorPorts{}, false
+	}
+	return ports, true
+}
+
+// TailLog returns up to maxBytes from the end of the supervisor's log file.
+func (s *Supervisor) TailLog(maxBytes int64) ([]byte, error) {
+	f, err := os.Open(s.logFilePath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := int64(0)
+	if info.Size() > maxBytes {
+		offset = info.Size() - maxBytes
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(f)
+}
+
+// isProcessAlive reports whether a process with the given PID still exists.
+func isProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// waitUntilReady polls the Temporal server until it responds or times out.
+func waitUntilReady(port int) error {
+	timeout := time.After(30 * time.Second)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	attemptCount := 0
+	for {
+		select {
+		case <-timeout:
+			log.Printf(i18n.T("TimeoutWaitingForTemporalServerToStart"), attemptCount)
+			return fmt.Errorf("timeout waiting for Temporal server to start")
+		case <-ticker.C:
+			attemptCount++
+			log.Printf(i18n.T("CheckingIfTemporalServerIsReady"), attemptCount)
+			if isTemporalServerRunning(port) {
+				log.Printf(i18n.T("TemporalServerIsNowReadyOnPort"), port)
+				return nil
+			}
+			log.Printf(i18n.T("TemporalServerNotReadyYet"), attemptCount)
+		}
+	}
+}
+
+// rotatingLogFile is an io.Writer over a log file that rotates itself once
+// it passes maxLogFileBytes, keeping up to maxLogBackups previous files
+// (temporal-service.log.1, .2, ...).
+type rotatingLogFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+func newRotatingLogFile(path string) (*rotatingLogFile, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingLogFile{path: path, file: f, size: info.Size()}, nil
+}
+
+func (r *rotatingLogFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > maxLogFileBytes {
+		if err := r.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingLogFile) rotateLocked() error {
+	r.file.Close()
+
+	for i := maxLogBackups - 1; i >= 1; i-- {
+		oldPath := fmt.Sprintf("%s.%d", r.path, i)
+		newPath := fmt.Sprintf("%s.%d", r.path, i+1)
+		os.Rename(oldPath, newPath)
+	}
+	os.Rename(r.path, fmt.Sprintf("%s.1", r.path))
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.size = 0
+	return nil
+}
+
+func (r *rotatingLogFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// registerSupervisorHandlers mounts /supervisor/status, /supervisor/logs,
+// and /supervisor/stop on mux.
+func registerSupervisorHandlers(mux *http.ServeMux, supervisor *Supervisor) {
+	mux.HandleFunc("/supervisor/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(supervisor.Status())
+	})
+
+	mux.HandleFunc("/supervisor/logs", func(w http.ResponseWriter, r *http.Request) {
+		data, err := supervisor.TailLog(64 * 1024)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(data)
+	})
+
+	mux.HandleFunc("/supervisor/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := supervisor.Stop(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
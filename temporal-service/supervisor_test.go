@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSupervisor(t *testing.T) *Supervisor {
+	t.Helper()
+	return &Supervisor{
+		cfg:      &Config{DBFilename: "test.db", LogFormat: "json", Namespace: "default"},
+		stateDir: t.TempDir(),
+		backoff:  restartInitialBackoff,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func TestWriteStateFilesRoundTrip(t *testing.T) {
+	s := newTestSupervisor(t)
+	s.ports = &PortConfig{TemporalPort: 7233, UIPort: 8233}
+
+	if err := s.writeStateFiles(12345); err != nil {
+		t.Fatalf("writeStateFiles failed: %v", err)
+	}
+
+	ports, ok := s.readPortsFile()
+	if !ok {
+		t.Fatal("expected readPortsFile to find the file just written")
+	}
+	if ports.PID != 12345 || ports.TemporalPort != 7233 || ports.UIPort != 8233 {
+		t.Errorf("unexpected ports read back: %+v", ports)
+	}
+}
+
+func TestRecordAndClearLiveness(t *testing.T) {
+	s := newTestSupervisor(t)
+	ports := &PortConfig{TemporalPort: 7233, UIPort: 8233}
+
+	if err := s.RecordLiveness(os.Getpid(), ports); err != nil {
+		t.Fatalf("RecordLiveness failed: %v", err)
+	}
+
+	status := s.Status()
+	if !status.Running || status.PID != os.Getpid() {
+		t.Errorf("expected Status to report the recorded PID as running, got: %+v", status)
+	}
+
+	s.ClearLiveness()
+
+	if _, ok := s.readPortsFile(); ok {
+		t.Error("expected ClearLiveness to remove the ports file")
+	}
+	if status := s.Status(); status.Running {
+		t.Errorf("expected Status to report not running after ClearLiveness, got: %+v", status)
+	}
+}
+
+func TestRotatingLogFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	r, err := newRotatingLogFile(path)
+	if err != nil {
+		t.Fatalf("newRotatingLogFile failed: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := r.Write(bytes.Repeat([]byte("a"), maxLogFileBytes)); err != nil {
+		t.Fatalf("initial write failed: %v", err)
+	}
+	// This write pushes us over maxLogFileBytes, which should rotate the
+	// file just written out to a .1 backup before writing "b" to a fresh one.
+	if _, err := r.Write([]byte("b")); err != nil {
+		t.Fatalf("write that should trigger rotation failed: %v", err)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated backup %s.1 to exist: %v", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read current log file: %v", err)
+	}
+	if string(data) != "b" {
+		t.Errorf("expected fresh log file to contain only the post-rotation write, got %q", data)
+	}
+}
+
+// TestStopDuringBackoffDoesNotHang is a regression test for a bug where
+// Stop() called while superviseRestarts was sleeping before a restart would
+// hang forever: the sleeping goroutine ignored s.stopping, spawned a
+// replacement process Stop no longer knew about, and restartWG.Wait() ended
+// up waiting on that new, unsupervised goroutine instead of returning.
+func TestStopDuringBackoffDoesNotHang(t *testing.T) {
+	s := newTestSupervisor(t)
+	s.backoff = 200 * time.Millisecond
+
+	logFile, err := newRotatingLogFile(s.logFilePath())
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+
+	cmd := exec.Command("sh", "-c", "exit 1")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start fake child: %v", err)
+	}
+
+	s.mu.Lock()
+	s.cmd = cmd
+	s.log = logFile
+	s.mu.Unlock()
+
+	s.restartWG.Add(1)
+	go s.superviseRestarts()
+
+	// Give superviseRestarts time to observe the exit and enter its backoff
+	// sleep, well before s.backoff elapses.
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Stop() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Stop returned an error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop() hung waiting on a goroutine spawned during backoff")
+	}
+}